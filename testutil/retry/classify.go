@@ -0,0 +1,94 @@
+package retry
+
+import "strings"
+
+// DefaultMaxErrors is the number of errors an ErrorClassifier keeps
+// when MaxErrors is not set.
+const DefaultMaxErrors = 5
+
+// ErrorClassifier is embedded in Counter, Timer and Backoff to let
+// callers classify which errors returned by Run's fn are worth
+// retrying, and to keep a bounded history of the most recent failures
+// for diagnostics.
+type ErrorClassifier struct {
+	// ShouldRetry, if set, is consulted by Run for errors that were
+	// not explicitly wrapped with Retryable. It should return true if
+	// the error is transient and the operation should be retried,
+	// e.g. retry on a net.Error timeout but abort on a 4xx-equivalent
+	// error. If nil, such errors are treated as fatal, matching Run's
+	// behavior without a classifier.
+	ShouldRetry func(error) bool
+
+	// MaxErrors bounds how many of the most recent errors Errors
+	// returns. If zero, DefaultMaxErrors is used.
+	MaxErrors int
+
+	errs []error
+}
+
+// recordError appends err to the bounded history, dropping the oldest
+// entry once MaxErrors is exceeded.
+func (c *ErrorClassifier) recordError(err error) {
+	if err == nil {
+		return
+	}
+	max := c.MaxErrors
+	if max <= 0 {
+		max = DefaultMaxErrors
+	}
+	c.errs = append(c.errs, err)
+	if len(c.errs) > max {
+		c.errs = c.errs[len(c.errs)-max:]
+	}
+}
+
+// shouldRetry reports whether err should be retried according to
+// ShouldRetry. Errors not explicitly wrapped with Retryable are fatal
+// by default, matching Run's behavior without a classifier, so this
+// returns false when ShouldRetry is not set.
+func (c *ErrorClassifier) shouldRetry(err error) bool {
+	if c.ShouldRetry == nil {
+		return false
+	}
+	return c.ShouldRetry(err)
+}
+
+// Errors returns the most recent errors recorded by Run, oldest
+// first, up to MaxErrors.
+func (c *ErrorClassifier) Errors() []error {
+	out := make([]error, len(c.errs))
+	copy(out, c.errs)
+	return out
+}
+
+// reset clears the recorded error history.
+func (c *ErrorClassifier) reset() {
+	c.errs = nil
+}
+
+// errorRecorder is implemented by retryers embedding ErrorClassifier,
+// and is used internally by Run to classify and collect errors
+// without depending on a concrete retryer type.
+type errorRecorder interface {
+	recordError(err error)
+	shouldRetry(err error) bool
+	Errors() []error
+}
+
+// MultiError is an aggregated error made up of the failures recorded
+// by an ErrorClassifier. It is surfaced through TimeoutError when a
+// Retryer that embeds ErrorClassifier is exhausted.
+type MultiError struct {
+	Errors []error
+}
+
+func (e *MultiError) Error() string {
+	if len(e.Errors) == 0 {
+		return "no errors"
+	}
+	parts := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		parts[i] = err.Error()
+	}
+	return strings.Join(parts, "; ")
+}
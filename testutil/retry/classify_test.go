@@ -0,0 +1,76 @@
+package retry
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// timeoutErr is a minimal net.Error that reports itself as a
+// temporary timeout.
+type timeoutErr struct{}
+
+func (timeoutErr) Timeout() bool   { return true }
+func (timeoutErr) Temporary() bool { return true }
+func (timeoutErr) Error() string   { return "i/o timeout" }
+
+func TestShouldRetry_ClassifiesRawErrors(t *testing.T) {
+	fatal := errors.New("404 not found")
+	c := &Counter{
+		Count: 3,
+		Wait:  time.Millisecond,
+		ErrorClassifier: ErrorClassifier{
+			ShouldRetry: func(err error) bool {
+				ne, ok := err.(net.Error)
+				return ok && ne.Timeout()
+			},
+		},
+	}
+
+	var calls int
+	err := Run(c, func() error {
+		calls++
+		if calls < 2 {
+			return timeoutErr{}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("got error %v want nil", err)
+	}
+	if got, want := calls, 2; got != want {
+		t.Fatalf("got %d calls want %d", got, want)
+	}
+	if got, want := len(c.Errors()), 1; got != want {
+		t.Fatalf("got %d recorded errors want %d", got, want)
+	}
+
+	c.Reset()
+	err = Run(c, func() error {
+		return fatal
+	})
+	if err != fatal {
+		t.Fatalf("got error %v want %v", err, fatal)
+	}
+	if got := c.Errors(); len(got) != 0 {
+		t.Fatalf("got %d recorded errors want 0, a fatal error should not be recorded", len(got))
+	}
+}
+
+func TestErrorClassifier_BoundedHistory(t *testing.T) {
+	c := &Counter{Count: 5, Wait: time.Millisecond, ErrorClassifier: ErrorClassifier{MaxErrors: 2}}
+	err := Run(c, func() error {
+		return Retryable(errors.New("nope"))
+	})
+	te, ok := err.(*TimeoutError)
+	if !ok {
+		t.Fatalf("got error of type %T want *TimeoutError", err)
+	}
+	if got, want := len(te.Errors), 2; got != want {
+		t.Fatalf("got %d errors want %d", got, want)
+	}
+	if te.Error() == "" {
+		t.Fatal("TimeoutError.Error() should not be empty")
+	}
+}
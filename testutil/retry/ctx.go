@@ -0,0 +1,104 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// ContextRetryer is a Retryer that can also be driven with a context,
+// so that a cancelled or expired context stops the wait between
+// attempts instead of letting it run to completion.
+type ContextRetryer interface {
+	Retryer
+
+	// NextCtx behaves like Next, except that the wait between
+	// attempts is interrupted as soon as ctx is done. If ctx is done
+	// before the retryer would otherwise continue, NextCtx calls
+	// t.FailNow() and returns false, just as an exhausted retryer
+	// would.
+	NextCtx(ctx context.Context, t Failer) bool
+}
+
+var (
+	_ ContextRetryer = (*Counter)(nil)
+	_ ContextRetryer = (*Timer)(nil)
+	_ ContextRetryer = (*Backoff)(nil)
+)
+
+// NextCtx returns true as long as the number of retries has not been
+// reached. The first invocation will return immediately. All
+// subsequent calls wait for the Wait period unless ctx is done first,
+// in which case NextCtx stops immediately.
+func (r *Counter) NextCtx(ctx context.Context, t Failer) bool {
+	if r.count == r.Count {
+		t.FailNow()
+		return false
+	}
+	if r.count > 0 {
+		if !sleepCtx(ctx, clockOrDefault(r.Clock), r.Wait) {
+			t.FailNow()
+			return false
+		}
+	}
+	r.count++
+	return true
+}
+
+// NextCtx returns true as long as the timeout has not elapsed. The
+// first invocation will set the deadline for the timeout and will
+// return immediately. All subsequent calls wait for the Wait period
+// unless ctx is done first, in which case NextCtx stops immediately.
+func (r *Timer) NextCtx(ctx context.Context, t Failer) bool {
+	c := clockOrDefault(r.Clock)
+	if r.stop.IsZero() {
+		r.stop = c.Now().Add(r.Timeout)
+		return true
+	}
+	// See the matching comment in Timer.Next: !Before (>=) rather than
+	// After (>) is intentional so that a Clock landing exactly on the
+	// deadline, which a fake Clock can do, is treated as expired.
+	if !c.Now().Before(r.stop) {
+		t.FailNow()
+		return false
+	}
+	if !sleepCtx(ctx, c, r.Wait) {
+		t.FailNow()
+		return false
+	}
+	return true
+}
+
+// NextCtx returns true as long as the number of attempts has not been
+// reached. The first invocation will return immediately. All
+// subsequent calls wait for an exponentially growing, jittered wait
+// period unless ctx is done first, in which case NextCtx stops
+// immediately.
+func (r *Backoff) NextCtx(ctx context.Context, t Failer) bool {
+	if r.attempt == r.MaxAttempts {
+		t.FailNow()
+		return false
+	}
+	if r.attempt > 0 {
+		if !sleepCtx(ctx, clockOrDefault(r.Clock), r.wait()) {
+			t.FailNow()
+			return false
+		}
+	}
+	r.attempt++
+	return true
+}
+
+// sleepCtx waits for d, measured by c, or until ctx is done, whichever
+// comes first. It reports whether the wait completed, i.e. false
+// means ctx ended the wait early.
+func sleepCtx(ctx context.Context, c Clock, d time.Duration) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case <-c.After(d):
+		return true
+	}
+}
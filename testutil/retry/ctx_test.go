@@ -0,0 +1,47 @@
+package retry
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNextCtx_Cancellation(t *testing.T) {
+	tests := []struct {
+		desc string
+		r    ContextRetryer
+	}{
+		{"counter", &Counter{Count: 5, Wait: time.Second}},
+		{"timer", &Timer{Timeout: 5 * time.Second, Wait: time.Second}},
+		{"backoff", &Backoff{Base: time.Second, MaxWait: time.Second, MaxAttempts: 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			f := new(failer)
+
+			if !tt.r.NextCtx(ctx, f) {
+				t.Fatal("first NextCtx should return true immediately")
+			}
+
+			cancel()
+			start := time.Now()
+			if tt.r.NextCtx(ctx, f) {
+				t.Fatal("NextCtx should return false once ctx is cancelled")
+			}
+			if dur := time.Since(start); dur > 100*time.Millisecond {
+				t.Fatalf("NextCtx took %v, should stop immediately on cancellation", dur)
+			}
+			if got, want := f.calls, 1; got != want {
+				t.Fatalf("got %d FailNow calls want %d", got, want)
+			}
+
+			tt.r.Reset()
+			ctx2 := context.Background()
+			if !tt.r.NextCtx(ctx2, f) {
+				t.Fatal("NextCtx should work normally after Reset")
+			}
+		})
+	}
+}
@@ -2,7 +2,10 @@
 // which can be used in tests.
 package retry
 
-import "time"
+import (
+	"math/rand"
+	"time"
+)
 
 const (
 	// Timeout is the default time span for which an operation
@@ -13,6 +16,23 @@ const (
 	Wait = 25 * time.Millisecond
 )
 
+const (
+	// BackoffBase is the default base wait time for a Backoff retryer.
+	BackoffBase = 10 * time.Millisecond
+
+	// BackoffMaxWait is the default cap on the wait time for a
+	// Backoff retryer.
+	BackoffMaxWait = time.Second
+
+	// BackoffMaxAttempts is the default number of attempts for a
+	// Backoff retryer.
+	BackoffMaxAttempts = 10
+
+	// BackoffJitter is the default jitter factor for a Backoff
+	// retryer, applied as +/- the percentage of the computed wait.
+	BackoffJitter = 0.5
+)
+
 // Retryer provides an interface for retrying an operation
 // repeatedly until it either succeeds or times out. The
 // Failer will be called when on timeout.
@@ -44,6 +64,42 @@ type Failer interface {
 	FailNow()
 }
 
+// Clock abstracts the passage of time so that Counter, Timer and
+// Backoff can be driven deterministically in tests. The zero value of
+// each retryer uses a real-time Clock; tests can substitute their own,
+// e.g. retrytest.NewFakeClock(), to advance time synthetically instead
+// of sleeping wall-clock time.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// Sleep pauses for at least d.
+	Sleep(d time.Duration)
+
+	// After returns a channel that receives the current time
+	// after d has elapsed.
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock implements Clock using the time package.
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// defaultClock is used by Counter, Timer and Backoff when their Clock
+// field is nil.
+var defaultClock Clock = realClock{}
+
+// clockOrDefault returns c, or defaultClock if c is nil.
+func clockOrDefault(c Clock) Clock {
+	if c == nil {
+		return defaultClock
+	}
+	return c
+}
+
 // R returns a Timer with default configuration.
 func R() *Timer {
 	return &Timer{Timeout: Timeout, Wait: Wait}
@@ -63,6 +119,14 @@ type Counter struct {
 	Count int
 	Wait  time.Duration
 
+	// Clock is used to wait between attempts. If nil, a real-time
+	// Clock is used.
+	Clock Clock
+
+	// ErrorClassifier configures which errors returned to Run are
+	// retried, and keeps a bounded history of the most recent ones.
+	ErrorClassifier
+
 	count int
 }
 
@@ -77,7 +141,7 @@ func (r *Counter) Next(t Failer) bool {
 		return false
 	}
 	if r.count > 0 {
-		time.Sleep(r.Wait)
+		clockOrDefault(r.Clock).Sleep(r.Wait)
 	}
 	r.count++
 	return true
@@ -86,6 +150,7 @@ func (r *Counter) Next(t Failer) bool {
 // Reset configures the retryer for re-use.
 func (r *Counter) Reset() {
 	r.count = 0
+	r.ErrorClassifier.reset()
 }
 
 // Timer implements a time-based retryer
@@ -96,6 +161,14 @@ type Timer struct {
 	Timeout time.Duration
 	Wait    time.Duration
 
+	// Clock is used to read the current time and wait between
+	// attempts. If nil, a real-time Clock is used.
+	Clock Clock
+
+	// ErrorClassifier configures which errors returned to Run are
+	// retried, and keeps a bounded history of the most recent ones.
+	ErrorClassifier
+
 	// stop is the timeout deadline.
 	// Set on the first invocation of Next().
 	stop time.Time
@@ -107,19 +180,132 @@ type Timer struct {
 // will return immediately. All subsequent
 // calls will return after the Wait period.
 func (r *Timer) Next(t Failer) bool {
+	c := clockOrDefault(r.Clock)
 	if r.stop.IsZero() {
-		r.stop = time.Now().Add(r.Timeout)
+		r.stop = c.Now().Add(r.Timeout)
 		return true
 	}
-	if time.Now().After(r.stop) {
+	// Using !Before (>=) rather than the previous strict After (>)
+	// means an attempt landing exactly on the deadline now fails
+	// immediately instead of getting one more iteration. That
+	// boundary is unreachable with a real Clock's sub-nanosecond
+	// precision, but a Clock like retrytest.FakeClock can land on it
+	// exactly, so the comparison needs to treat "at the deadline" as
+	// expired for Next to behave deterministically under it.
+	if !c.Now().Before(r.stop) {
 		t.FailNow()
 		return false
 	}
-	time.Sleep(r.Wait)
+	c.Sleep(r.Wait)
 	return true
 }
 
 // Reset configures the retryer for re-use.
 func (r *Timer) Reset() {
 	r.stop = time.Time{}
+	r.ErrorClassifier.reset()
+}
+
+// DefaultBackoff returns a Backoff retryer with default configuration.
+// The wait between attempts grows exponentially from BackoffBase up
+// to BackoffMaxWait, and up to BackoffMaxAttempts are made.
+func DefaultBackoff() *Backoff {
+	return &Backoff{
+		Base:        BackoffBase,
+		MaxWait:     BackoffMaxWait,
+		MaxAttempts: BackoffMaxAttempts,
+		Jitter:      BackoffJitter,
+	}
+}
+
+// Backoff implements a retryer which waits an exponentially growing
+// interval between attempts, capped at MaxWait and randomized by
+// Jitter to avoid a thundering herd of callers retrying in lockstep
+// against the same Consul cluster.
+//
+// The first operation will be executed immediately and all subsequent
+// operations will return after Wait = Base * 2^attempt, capped at
+// MaxWait and adjusted by +/- Jitter percent.
+type Backoff struct {
+	// Base is the wait duration before the first retry. Subsequent
+	// waits double each attempt.
+	Base time.Duration
+
+	// MaxWait caps the computed wait duration between attempts. The
+	// cap is applied before Jitter, so a jittered wait can exceed
+	// MaxWait by up to Jitter percent.
+	MaxWait time.Duration
+
+	// MaxAttempts is the number of attempts to make before giving up.
+	MaxAttempts int
+
+	// Jitter is the fraction, in [0, 1], by which the computed wait is
+	// randomly adjusted up or down. A Jitter of 0.1 means the actual
+	// wait will fall within +/- 10% of the computed value.
+	Jitter float64
+
+	// Rand is used to compute the jitter. If nil, the math/rand
+	// package-level functions are used. Tests can inject a
+	// deterministic *rand.Rand here.
+	Rand *rand.Rand
+
+	// Clock is used to wait between attempts. If nil, a real-time
+	// Clock is used.
+	Clock Clock
+
+	// ErrorClassifier configures which errors returned to Run are
+	// retried, and keeps a bounded history of the most recent ones.
+	ErrorClassifier
+
+	attempt int
+}
+
+// Next returns true as long as the number of attempts has not been
+// reached. The first invocation will return immediately. All
+// subsequent calls will return after an exponentially growing,
+// jittered wait period.
+func (r *Backoff) Next(t Failer) bool {
+	if r.attempt == r.MaxAttempts {
+		t.FailNow()
+		return false
+	}
+	if r.attempt > 0 {
+		clockOrDefault(r.Clock).Sleep(r.wait())
+	}
+	r.attempt++
+	return true
+}
+
+// Reset configures the retryer for re-use.
+func (r *Backoff) Reset() {
+	r.attempt = 0
+	r.ErrorClassifier.reset()
+}
+
+// wait computes the jittered, capped wait duration for the current
+// attempt.
+func (r *Backoff) wait() time.Duration {
+	d := r.Base << uint(r.attempt-1)
+	if d <= 0 || d > r.MaxWait {
+		d = r.MaxWait
+	}
+	if r.Jitter <= 0 {
+		return d
+	}
+	// r.Rand is a caller-provided *rand.Rand and, per its docs, is not
+	// safe for concurrent use; callers sharing one across goroutines
+	// are responsible for their own synchronization. Without one, use
+	// the math/rand package-level functions, which are safe for
+	// concurrent use, so that many Backoff retryers retrying in
+	// parallel (the thundering-herd case this type exists for) don't
+	// race on a shared *rand.Rand.
+	var f float64
+	if r.Rand != nil {
+		f = r.Rand.Float64()
+	} else {
+		f = rand.Float64()
+	}
+	delta := float64(d) * r.Jitter
+	min, max := float64(d)-delta, float64(d)+delta
+	return time.Duration(min + f*(max-min))
 }
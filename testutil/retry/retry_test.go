@@ -1,28 +1,43 @@
 package retry
 
 import (
+	"math/rand"
+	"reflect"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/hashicorp/consul/testutil/retry/retrytest"
 )
 
 type failer struct{ calls int }
 
 func (f *failer) FailNow() { f.calls++ }
 
-// delta defines the time band a test run should complete in.
-var delta = 5 * time.Millisecond
-
 func TestRetryer(t *testing.T) {
 	tests := []struct {
 		desc string
 		r    Retryer
+		// set assigns a FakeClock to the Retryer under test.
+		set func(Retryer, *retrytest.FakeClock)
 	}{
-		{"counter", &Counter{Count: 3, Wait: 10 * time.Millisecond}},
-		{"timer", &Timer{Timeout: 20 * time.Millisecond, Wait: 10 * time.Millisecond}},
+		{
+			"counter",
+			&Counter{Count: 3, Wait: 10 * time.Millisecond},
+			func(r Retryer, c *retrytest.FakeClock) { r.(*Counter).Clock = c },
+		},
+		{
+			"timer",
+			&Timer{Timeout: 20 * time.Millisecond, Wait: 10 * time.Millisecond},
+			func(r Retryer, c *retrytest.FakeClock) { r.(*Timer).Clock = c },
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
+			clock := retrytest.NewFakeClock()
+			tt.set(tt.r, clock)
+
 			var n int
 			f := new(failer)
 			start := time.Now()
@@ -36,12 +51,115 @@ func TestRetryer(t *testing.T) {
 			if got, want := f.calls, 1; got != want {
 				t.Fatalf("got %d FailNow calls want %d", got, want)
 			}
-			// since the first iteration happens immediately
-			// the retryer waits only twice for three iterations.
+			// a FakeClock advances synthetically, so driving the
+			// retryer with one should not block on wall-clock time.
+			if dur > 5*time.Millisecond {
+				t.Fatalf("loop took %v real time, want it to be driven by the fake clock", dur)
+			}
+			// since the first iteration happens immediately the
+			// retryer waits only twice for three iterations.
 			// order of events: (true, wait, true, wait, true, false)
-			if got, want := dur, 20*time.Millisecond; got < (want-delta) || got > (want+delta) {
-				t.Fatalf("loop took %v want %v (+/- %v)", got, want, delta)
+			if got, want := clock.Sleeps(), []time.Duration{10 * time.Millisecond, 10 * time.Millisecond}; !reflect.DeepEqual(got, want) {
+				t.Fatalf("got sleeps %v want %v", got, want)
 			}
 		})
 	}
 }
+
+func TestBackoff(t *testing.T) {
+	clock := retrytest.NewFakeClock()
+	r := &Backoff{
+		Base:        10 * time.Millisecond,
+		MaxWait:     100 * time.Millisecond,
+		MaxAttempts: 6,
+		Jitter:      0.25,
+		Rand:        rand.New(rand.NewSource(1)),
+		Clock:       clock,
+	}
+
+	var n int
+	f := new(failer)
+	start := time.Now()
+	for r.Next(f) {
+		n++
+	}
+	dur := time.Since(start)
+	if got, want := n, r.MaxAttempts; got != want {
+		t.Fatalf("got %d attempts want %d", got, want)
+	}
+	if got, want := f.calls, 1; got != want {
+		t.Fatalf("got %d FailNow calls want %d", got, want)
+	}
+	// a FakeClock advances synthetically, so driving the retryer with
+	// one should not block on wall-clock time.
+	if dur > 5*time.Millisecond {
+		t.Fatalf("loop took %v real time, want it to be driven by the fake clock", dur)
+	}
+
+	waits := clock.Sleeps()
+	if got, want := len(waits), r.MaxAttempts-1; got != want {
+		t.Fatalf("got %d recorded waits want %d", got, want)
+	}
+	for i, w := range waits {
+		wantBase := r.Base << uint(i)
+		if wantBase <= 0 || wantBase > r.MaxWait {
+			wantBase = r.MaxWait
+		}
+		min := time.Duration(float64(wantBase) * (1 - r.Jitter))
+		max := time.Duration(float64(wantBase) * (1 + r.Jitter))
+		if w < min || w > max {
+			t.Fatalf("wait %d: got %v want between %v and %v", i, w, min, max)
+		}
+	}
+}
+
+func TestBackoff_MaxWaitCap(t *testing.T) {
+	clock := retrytest.NewFakeClock()
+	r := &Backoff{
+		Base:        10 * time.Millisecond,
+		MaxWait:     15 * time.Millisecond,
+		MaxAttempts: 10,
+		Jitter:      0,
+		Rand:        rand.New(rand.NewSource(1)),
+		Clock:       clock,
+	}
+	f := new(failer)
+	for i := 0; i < r.MaxAttempts-1; i++ {
+		if !r.Next(f) {
+			t.Fatalf("Next returned false early at attempt %d", i)
+		}
+	}
+	for i, w := range clock.Sleeps() {
+		if w > r.MaxWait {
+			t.Fatalf("wait %d: got %v exceeds MaxWait %v", i, w, r.MaxWait)
+		}
+	}
+}
+
+func TestDefaultBackoff(t *testing.T) {
+	r := DefaultBackoff()
+	if r.Base != BackoffBase || r.MaxWait != BackoffMaxWait ||
+		r.MaxAttempts != BackoffMaxAttempts || r.Jitter != BackoffJitter {
+		t.Fatalf("DefaultBackoff() did not return expected defaults: %+v", r)
+	}
+}
+
+// TestDefaultBackoff_Concurrent guards against a data race on the
+// default source of randomness when many DefaultBackoff retryers,
+// none with their own Rand, compute jitter concurrently. Run with
+// -race to catch a regression.
+func TestDefaultBackoff_Concurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := DefaultBackoff()
+			r.Base, r.MaxWait, r.MaxAttempts = time.Millisecond, 2*time.Millisecond, 5
+			f := new(failer)
+			for r.Next(f) {
+			}
+		}()
+	}
+	wg.Wait()
+}
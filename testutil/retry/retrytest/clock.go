@@ -0,0 +1,70 @@
+// Package retrytest provides a fake retry.Clock for driving
+// testutil/retry loops deterministically, without sleeping wall-clock
+// time.
+package retrytest
+
+import (
+	"sync"
+	"time"
+)
+
+// NewFakeClock returns a FakeClock starting at the Unix epoch.
+func NewFakeClock() *FakeClock {
+	return &FakeClock{now: time.Unix(0, 0)}
+}
+
+// FakeClock implements retry.Clock by advancing a synthetic clock
+// instead of waiting wall-clock time. Sleep and After both advance
+// the clock by the requested duration and return immediately, so
+// retry loops driven by a FakeClock run as fast as the test can call
+// them. Sleeps records every duration waited so tests can assert on
+// the exact sequence.
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	sleeps []time.Duration
+}
+
+// Now returns the current synthetic time.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Sleep advances the synthetic clock by d and returns immediately.
+func (c *FakeClock) Sleep(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	c.sleeps = append(c.sleeps, d)
+}
+
+// After advances the synthetic clock by d, as Sleep does, and returns
+// a channel that has the resulting time already available to read.
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.Sleep(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.Now()
+	return ch
+}
+
+// Sleeps returns the durations passed to Sleep or After so far, in
+// order.
+func (c *FakeClock) Sleeps() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]time.Duration, len(c.sleeps))
+	copy(out, c.sleeps)
+	return out
+}
+
+// Advance moves the synthetic clock forward by d without recording a
+// sleep. It is useful for simulating the passage of time between
+// retry attempts driven by something other than Sleep or After, e.g.
+// a Timer's deadline check.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
@@ -0,0 +1,179 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Run invokes fn repeatedly according to r until fn succeeds, fn
+// returns a fatal error, or r is exhausted.
+//
+// A nil error from fn is treated as success and Run returns nil
+// immediately. An error wrapped with Retryable is always treated as a
+// transient failure. Any other error is treated as transient too if r
+// embeds an ErrorClassifier with a ShouldRetry func that returns true
+// for it; otherwise it is fatal and returned to the caller immediately
+// without retrying. Either way, transient errors are recorded on r's
+// ErrorClassifier, if any, for later inspection via Errors.
+//
+// If r is exhausted before fn succeeds, Run returns a *TimeoutError
+// describing how long it ran, how many attempts were made and the
+// errors that were seen, instead of calling Failer.FailNow. This makes
+// Run usable outside of tests; use RunWith to get the previous
+// behavior of failing a *testing.T on exhaustion.
+func Run(r Retryer, fn func() error) error {
+	start := time.Now()
+	res := runLoop(r.Next, r, fn)
+	if res.success {
+		return nil
+	}
+	if res.fatal != nil {
+		return res.fatal
+	}
+	return res.timeoutError(start)
+}
+
+// RunCtx behaves like Run, but uses r's NextCtx instead of Next, so
+// that the wait between attempts is interrupted as soon as ctx is
+// done. This lets a backoff be bounded by a caller's deadline instead
+// of always running it to completion, e.g. in server code handling a
+// request with its own deadline.
+//
+// If ctx is done before fn succeeds, RunCtx returns ctx.Err() instead
+// of a *TimeoutError.
+func RunCtx(ctx context.Context, r ContextRetryer, fn func() error) error {
+	start := time.Now()
+	next := func(t Failer) bool { return r.NextCtx(ctx, t) }
+	res := runLoop(next, r, fn)
+	if res.success {
+		return nil
+	}
+	if res.fatal != nil {
+		return res.fatal
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return res.timeoutError(start)
+}
+
+// RunWith behaves like Run but adapts the result to a Failer, calling
+// t.FailNow() if r is exhausted before fn succeeds. This preserves
+// the familiar testing.T-failing behavior of the Next-based API while
+// still letting callers use the Run-style callback.
+func RunWith(t Failer, r Retryer, fn func() error) {
+	if err := Run(r, fn); err != nil {
+		t.FailNow()
+	}
+}
+
+// runResult collects the outcome of the shared loop in runLoop, which
+// Run and RunCtx each translate into the appropriate returned error.
+type runResult struct {
+	attempts int
+	lastErr  error
+	errs     []error
+	fatal    error
+	success  bool
+}
+
+// timeoutError builds the *TimeoutError for a retryer that was
+// exhausted, given the time Run or RunCtx started.
+func (res runResult) timeoutError(start time.Time) *TimeoutError {
+	return &TimeoutError{
+		Elapsed:  time.Since(start),
+		Attempts: res.attempts,
+		LastErr:  res.lastErr,
+		Errors:   res.errs,
+	}
+}
+
+// runLoop drives fn via next, which is either r.Next or an r.NextCtx
+// closure, applying the same success/Retryable/ShouldRetry/fatal
+// classification and error bookkeeping for both Run and RunCtx.
+func runLoop(next func(Failer) bool, r Retryer, fn func() error) runResult {
+	var res runResult
+	rec, _ := r.(errorRecorder)
+	for next(discardFailer{}) {
+		res.attempts++
+		err := fn()
+		if err == nil {
+			res.success = true
+			return res
+		}
+		retryable := false
+		if re, ok := err.(*retryableError); ok {
+			retryable = true
+			err = re.err
+		} else if rec != nil {
+			retryable = rec.shouldRetry(err)
+		}
+		if !retryable {
+			res.fatal = err
+			return res
+		}
+		if rec != nil {
+			rec.recordError(err)
+		}
+		res.lastErr = err
+	}
+	if rec != nil {
+		res.errs = rec.Errors()
+	}
+	return res
+}
+
+// discardFailer adapts runLoop's internal use of Next/NextCtx, which
+// expect a Failer, to one that does not fail a test: Run and RunCtx
+// surface exhaustion as a returned *TimeoutError instead.
+type discardFailer struct{}
+
+func (discardFailer) FailNow() {}
+
+// Retryable wraps err to tell Run that the failure is transient and
+// the operation should be retried. A nil err is passed through
+// unchanged.
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &retryableError{err}
+}
+
+// retryableError marks an error as transient for Run.
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string { return e.err.Error() }
+
+// TimeoutError is returned by Run or RunCtx when the Retryer is
+// exhausted before fn succeeds.
+type TimeoutError struct {
+	// Elapsed is the time spent retrying.
+	Elapsed time.Duration
+
+	// Attempts is the number of times fn was invoked.
+	Attempts int
+
+	// LastErr is the last error returned by fn, unwrapped from
+	// Retryable. It is nil if fn always returned a fatal error (which
+	// Run would have returned directly) or was never called.
+	LastErr error
+
+	// Errors holds the most recent errors recorded by r's
+	// ErrorClassifier, oldest first, if r embeds one. It is nil for
+	// retryers that don't.
+	Errors []error
+}
+
+func (e *TimeoutError) Error() string {
+	if len(e.Errors) > 0 {
+		return fmt.Sprintf("timeout after %s (%d attempts): %s", e.Elapsed, e.Attempts, (&MultiError{Errors: e.Errors}))
+	}
+	if e.LastErr == nil {
+		return fmt.Sprintf("timeout after %s (%d attempts)", e.Elapsed, e.Attempts)
+	}
+	return fmt.Sprintf("timeout after %s (%d attempts): %s", e.Elapsed, e.Attempts, e.LastErr)
+}
@@ -0,0 +1,117 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRun_Success(t *testing.T) {
+	var calls int
+	err := Run(&Counter{Count: 5, Wait: time.Millisecond}, func() error {
+		calls++
+		if calls < 3 {
+			return Retryable(errors.New("not yet"))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("got error %v want nil", err)
+	}
+	if got, want := calls, 3; got != want {
+		t.Fatalf("got %d calls want %d", got, want)
+	}
+}
+
+func TestRun_FatalErrorStopsImmediately(t *testing.T) {
+	var calls int
+	fatal := errors.New("fatal")
+	err := Run(&Counter{Count: 5, Wait: time.Millisecond}, func() error {
+		calls++
+		return fatal
+	})
+	if err != fatal {
+		t.Fatalf("got error %v want %v", err, fatal)
+	}
+	if got, want := calls, 1; got != want {
+		t.Fatalf("got %d calls want %d", got, want)
+	}
+}
+
+func TestRun_TimeoutError(t *testing.T) {
+	last := errors.New("still failing")
+	err := Run(&Counter{Count: 3, Wait: time.Millisecond}, func() error {
+		return Retryable(last)
+	})
+	te, ok := err.(*TimeoutError)
+	if !ok {
+		t.Fatalf("got error of type %T want *TimeoutError", err)
+	}
+	if got, want := te.Attempts, 3; got != want {
+		t.Fatalf("got %d attempts want %d", got, want)
+	}
+	if te.LastErr != last {
+		t.Fatalf("got LastErr %v want %v", te.LastErr, last)
+	}
+}
+
+func TestRunCtx_Success(t *testing.T) {
+	var calls int
+	err := RunCtx(context.Background(), &Counter{Count: 5, Wait: time.Millisecond}, func() error {
+		calls++
+		if calls < 3 {
+			return Retryable(errors.New("not yet"))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("got error %v want nil", err)
+	}
+	if got, want := calls, 3; got != want {
+		t.Fatalf("got %d calls want %d", got, want)
+	}
+}
+
+func TestRunCtx_CancelledContextStopsRetrying(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int
+	err := RunCtx(ctx, &Counter{Count: 5, Wait: time.Second}, func() error {
+		calls++
+		if calls == 1 {
+			cancel()
+		}
+		return Retryable(errors.New("still failing"))
+	})
+	if err != context.Canceled {
+		t.Fatalf("got error %v want %v", err, context.Canceled)
+	}
+	if got, want := calls, 1; got != want {
+		t.Fatalf("got %d calls want %d", got, want)
+	}
+}
+
+func TestRunCtx_FatalErrorStopsImmediately(t *testing.T) {
+	var calls int
+	fatal := errors.New("fatal")
+	err := RunCtx(context.Background(), &Counter{Count: 5, Wait: time.Millisecond}, func() error {
+		calls++
+		return fatal
+	})
+	if err != fatal {
+		t.Fatalf("got error %v want %v", err, fatal)
+	}
+	if got, want := calls, 1; got != want {
+		t.Fatalf("got %d calls want %d", got, want)
+	}
+}
+
+func TestRunWith_FailsTestOnExhaustion(t *testing.T) {
+	f := new(failer)
+	RunWith(f, &Counter{Count: 2, Wait: time.Millisecond}, func() error {
+		return Retryable(errors.New("nope"))
+	})
+	if got, want := f.calls, 1; got != want {
+		t.Fatalf("got %d FailNow calls want %d", got, want)
+	}
+}